@@ -3,12 +3,14 @@ package main
 import (
 	"context"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"glyph-foundry/metrics-collector/internal/collector"
 	"glyph-foundry/metrics-collector/internal/config"
+	"glyph-foundry/metrics-collector/internal/exporter"
 	"glyph-foundry/metrics-collector/internal/protocol"
 )
 
@@ -16,23 +18,36 @@ func main() {
 	log.Println("Starting Glyph Foundry Metrics Collector...")
 
 	cfg := config.Load()
-	log.Printf("Configuration: API=%s, Interval=%s, Batch=%d, Workers=%d",
-		cfg.GlyphAPIURL, cfg.CollectionInterval, cfg.MaxMetricsPerBatch, cfg.WorkerCount)
+	log.Printf("Configuration: Sink=%s, Target=%s, Interval=%s, Batch=%d, Workers=%d",
+		cfg.SinkType, cfg.SinkTargetURL, cfg.CollectionInterval, cfg.MaxMetricsPerBatch, cfg.WorkerCount)
 
-	handler := protocol.NewHandler(cfg.GlyphAPIURL, cfg.TenantID)
+	sink, err := protocol.NewSink(protocol.SinkConfig{
+		SinkType:      cfg.SinkType,
+		TargetURL:     cfg.SinkTargetURL,
+		TenantID:      cfg.TenantID,
+		TLSCertFile:   cfg.TLSCertFile,
+		TLSKeyFile:    cfg.TLSKeyFile,
+		TLSCAFile:     cfg.TLSCAFile,
+		AuthTokenFile: cfg.AuthTokenFile,
+		TenantRoutes:  cfg.TenantRoutes,
+	})
+	if err != nil {
+		log.Fatalf("Failed to build sink: %v", err)
+	}
 
 	sourceID := os.Getenv("SOURCE_ID")
-	c := collector.New(
-		handler,
-		sourceID,
-		cfg.CollectionInterval,
-		cfg.MaxMetricsPerBatch,
-		cfg.BufferSize,
-		cfg.EnableCPUMetrics,
-		cfg.EnableMemoryMetrics,
-		cfg.EnableNetworkMetrics,
-		cfg.EnableDiskMetrics,
-	)
+	c := collector.New(sink, sourceID, cfg)
+
+	var exp *exporter.Exporter
+	if cfg.ExporterListen != "" {
+		exp = exporter.New(c, cfg.ExporterListen)
+		go func() {
+			log.Printf("Starting metrics exporter on %s", cfg.ExporterListen)
+			if err := exp.Start(); err != nil && err != http.ErrServerClosed {
+				log.Printf("Exporter stopped: %v", err)
+			}
+		}()
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -51,5 +66,11 @@ func main() {
 		log.Fatalf("Collector failed: %v", err)
 	}
 
+	if exp != nil {
+		if err := exp.Shutdown(context.Background()); err != nil {
+			log.Printf("Failed to shut down exporter: %v", err)
+		}
+	}
+
 	log.Println("Metrics collector stopped")
 }
@@ -0,0 +1,155 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"glyph-foundry/metrics-collector/internal/protocol"
+)
+
+// Numeric is the set of sample types the aggregation reducers accept. It
+// deliberately omits bool: a boolean sample has no natural sum or ordering,
+// so a future bool-valued metric type would need its own 0/1 conversion
+// before reaching these reducers rather than a reducer that fakes one.
+type Numeric interface {
+	~float64 | ~float32 | ~int64 | ~int
+}
+
+// reduce left-folds vals with fn. It guards the empty-input case the way
+// cc-metric-collector's sumAnyType does, rather than silently returning a
+// zero value that would look like real data.
+func reduce[T Numeric](vals []T, fn func(acc, v T) T) (T, error) {
+	if len(vals) == 0 {
+		var zero T
+		return zero, fmt.Errorf("reduce: empty input")
+	}
+
+	acc := vals[0]
+	for _, v := range vals[1:] {
+		acc = fn(acc, v)
+	}
+	return acc, nil
+}
+
+func reduceMin[T Numeric](vals []T) (T, error) {
+	return reduce(vals, func(acc, v T) T {
+		if v < acc {
+			return v
+		}
+		return acc
+	})
+}
+
+func reduceMax[T Numeric](vals []T) (T, error) {
+	return reduce(vals, func(acc, v T) T {
+		if v > acc {
+			return v
+		}
+		return acc
+	})
+}
+
+func reduceSum[T Numeric](vals []T) (T, error) {
+	return reduce(vals, func(acc, v T) T { return acc + v })
+}
+
+func reduceAvg[T Numeric](vals []T) (float64, error) {
+	sum, err := reduceSum(vals)
+	if err != nil {
+		return 0, err
+	}
+	return float64(sum) / float64(len(vals)), nil
+}
+
+// reduceMedian sorts a copy of vals in place and returns the middle value,
+// or the average of the two middle values for an even-length input.
+func reduceMedian[T Numeric](vals []T) (float64, error) {
+	if len(vals) == 0 {
+		return 0, fmt.Errorf("reduce: empty input")
+	}
+
+	sorted := append([]T(nil), vals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return float64(sorted[mid]), nil
+	}
+	return (float64(sorted[mid-1]) + float64(sorted[mid])) / 2, nil
+}
+
+// aggregateWindow buffers raw samples for one (metric_name, labels)
+// fingerprint across an aggregation window. metric retains the last-seen
+// sample so the derived metrics can reuse its name, type, labels, and
+// source on flush.
+type aggregateWindow struct {
+	metric protocol.MetricData
+	values []float64
+}
+
+// Aggregator buffers raw samples per fingerprint across an aggregation
+// window and, on Flush, emits derived <name>_min/_max/_avg/_median/_sum
+// metrics -- dramatically cutting backend load at high collection
+// frequencies compared to shipping every raw sample.
+type Aggregator struct {
+	mu      sync.Mutex
+	windows map[string]*aggregateWindow
+}
+
+func NewAggregator() *Aggregator {
+	return &Aggregator{windows: make(map[string]*aggregateWindow)}
+}
+
+// Add buffers metric under its (metric_name, labels) fingerprint.
+func (a *Aggregator) Add(metric protocol.MetricData) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := fingerprint(metric.MetricName, metric.Labels)
+	w, ok := a.windows[key]
+	if !ok {
+		w = &aggregateWindow{}
+		a.windows[key] = w
+	}
+	w.metric = metric
+	w.values = append(w.values, metric.MetricValue)
+}
+
+// Flush computes min/max/avg/median/sum for every buffered fingerprint and
+// resets the buffers for the next window. Windows with no samples (which
+// shouldn't occur, since Add is what creates them) are skipped rather than
+// emitting reducer errors.
+func (a *Aggregator) Flush() []protocol.MetricData {
+	a.mu.Lock()
+	windows := a.windows
+	a.windows = make(map[string]*aggregateWindow)
+	a.mu.Unlock()
+
+	derived := make([]protocol.MetricData, 0, len(windows)*5)
+	for _, w := range windows {
+		if len(w.values) == 0 {
+			continue
+		}
+
+		min, _ := reduceMin(w.values)
+		max, _ := reduceMax(w.values)
+		sum, _ := reduceSum(w.values)
+		avg, _ := reduceAvg(w.values)
+		median, _ := reduceMedian(w.values)
+
+		derived = append(derived,
+			derivedMetric(w.metric, "_min", min),
+			derivedMetric(w.metric, "_max", max),
+			derivedMetric(w.metric, "_avg", avg),
+			derivedMetric(w.metric, "_median", median),
+			derivedMetric(w.metric, "_sum", sum),
+		)
+	}
+
+	return derived
+}
+
+func derivedMetric(base protocol.MetricData, suffix string, value float64) protocol.MetricData {
+	return protocol.CreateMetric(base.MetricName+suffix, value, base.MetricType, base.SourceID, base.Labels)
+}
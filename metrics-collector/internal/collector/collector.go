@@ -2,60 +2,159 @@ package collector
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
+
+	"glyph-foundry/metrics-collector/internal/config"
 	"glyph-foundry/metrics-collector/internal/protocol"
-	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/disk"
-	"github.com/shirou/gopsutil/v3/mem"
-	"github.com/shirou/gopsutil/v3/net"
+	"glyph-foundry/metrics-collector/internal/spool"
 )
 
+// spoolReplayPollInterval is how often replayLoop checks the spool for new
+// files after draining it completely, rather than busy-looping Replay calls
+// against an empty directory.
+const spoolReplayPollInterval = 30 * time.Second
+
+// registeredSource pairs an initialized MetricSource with the config block
+// that gates whether it runs on a given tick, and the interval it's polled
+// on -- its own cfg.Interval if set, else the collector's global interval.
+type registeredSource struct {
+	source   MetricSource
+	cfg      *config.CollectorConfig
+	interval time.Duration
+}
+
 type Collector struct {
-	handler    *protocol.Handler
-	sourceID   string
-	interval   time.Duration
-	batchSize  int
+	sink      protocol.Sink
+	sourceID  string
+	interval  time.Duration
+	batchSize int
+	sources   []registeredSource
+
+	// rawBuffer carries every sample straight from the sources; it is
+	// consumed by aggregateLoop, which either passes samples through to
+	// buffer unchanged or folds them into aggregator and flushes derived
+	// metrics on aggWindow, depending on configuration.
+	rawBuffer  chan protocol.MetricData
 	buffer     chan protocol.MetricData
-	enableCPU  bool
-	enableMem  bool
-	enableNet  bool
-	enableDisk bool
+	aggregator *Aggregator
+	aggWindow  time.Duration
+	keepRaw    bool
+
+	// registry retains the most recent sample per (metric_name, labels)
+	// fingerprint, keyed by the string returned by fingerprint(). It backs
+	// pull-based consumers (the Prometheus exporter) alongside the push
+	// sendLoop, so both paths read from the same data.
+	registry sync.Map
+
+	// spool is nil unless SpoolDir is configured, in which case a batch
+	// that fails to send is appended to it instead of only being logged,
+	// and replayLoop drains it back into the sink once it recovers.
+	spool *spool.Spool
 }
 
-func New(handler *protocol.Handler, sourceID string, interval time.Duration, batchSize int, bufferSize int,
-	enableCPU, enableMem, enableNet, enableDisk bool) *Collector {
-	
+func New(sink protocol.Sink, sourceID string, cfg *config.Config) *Collector {
 	if sourceID == "" {
 		hostname, _ := os.Hostname()
 		sourceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
 	}
 
-	return &Collector{
-		handler:    handler,
-		sourceID:   sourceID,
-		interval:   interval,
-		batchSize:  batchSize,
-		buffer:     make(chan protocol.MetricData, bufferSize),
-		enableCPU:  enableCPU,
-		enableMem:  enableMem,
-		enableNet:  enableNet,
-		enableDisk: enableDisk,
+	c := &Collector{
+		sink:      sink,
+		sourceID:  sourceID,
+		interval:  cfg.CollectionInterval,
+		batchSize: cfg.MaxMetricsPerBatch,
+		rawBuffer: make(chan protocol.MetricData, cfg.BufferSize),
+		buffer:    make(chan protocol.MetricData, cfg.BufferSize),
+		aggWindow: cfg.AggregationWindow,
+		keepRaw:   cfg.AggregationKeepRaw,
+	}
+
+	if cfg.AggregationWindow > 0 {
+		c.aggregator = NewAggregator()
+	}
+
+	if cfg.SpoolDir != "" {
+		sp, err := spool.Open(cfg.SpoolDir, cfg.SpoolMaxBytes)
+		if err != nil {
+			log.Printf("Failed to open spool dir %s, spooling disabled: %v", cfg.SpoolDir, err)
+		} else {
+			c.spool = sp
+		}
+	}
+
+	sharedLabels := map[string]string{
+		"collector": "glyph-metrics",
+		"runtime":   runtime.Version(),
+	}
+
+	c.register(&CPUSource{}, cfg.CPU, sharedLabels)
+	c.register(&MemSource{}, cfg.Mem, sharedLabels)
+	c.register(&NetSource{}, cfg.Net, sharedLabels)
+	c.register(&DiskSource{}, cfg.Disk, sharedLabels)
+	c.register(&InfinibandSource{}, cfg.Infiniband, sharedLabels)
+	c.register(&GPUSource{}, cfg.GPU, sharedLabels)
+	c.register(&ProcessSource{}, cfg.Process, sharedLabels)
+
+	return c
+}
+
+// register initializes source and, if initialization succeeds, adds it to
+// c.sources, each of which collectLoop polls on its own ticker. A source that fails
+// to initialize (e.g. no NVML driver present for GPUSource) is logged and
+// skipped rather than failing collector startup entirely.
+func (c *Collector) register(source MetricSource, cfg *config.CollectorConfig, sharedLabels map[string]string) {
+	if err := source.Init(SourceInit{Config: cfg, SourceID: c.sourceID, SharedLabels: sharedLabels}); err != nil {
+		log.Printf("Failed to init %s collector: %v", source.Name(), err)
+		return
 	}
+
+	interval := c.interval
+	if cfg.Interval != "" {
+		parsed, err := time.ParseDuration(cfg.Interval)
+		if err != nil {
+			log.Printf("Invalid interval %q for %s collector, using default %s: %v", cfg.Interval, source.Name(), c.interval, err)
+		} else {
+			interval = parsed
+		}
+	}
+
+	c.sources = append(c.sources, registeredSource{source: source, cfg: cfg, interval: interval})
 }
 
 func (c *Collector) Start(ctx context.Context) error {
+	if c.spool != nil {
+		if err := c.spool.Replay(ctx, c.replaySpooledBatch); err != nil {
+			log.Printf("Startup spool replay stopped early: %v", err)
+		}
+	}
+
 	var wg sync.WaitGroup
 
+	// Each stage closes the channel it alone writes to once it returns, so
+	// the next stage down the pipeline can drain and exit in turn.
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		c.collectLoop(ctx)
+		close(c.rawBuffer)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.aggregateLoop(ctx)
+		close(c.buffer)
 	}()
 
 	wg.Add(1)
@@ -64,15 +163,44 @@ func (c *Collector) Start(ctx context.Context) error {
 		c.sendLoop(ctx)
 	}()
 
+	if c.spool != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.replayLoop(ctx)
+		}()
+	}
+
 	<-ctx.Done()
-	close(c.buffer)
 	wg.Wait()
-	
+
+	if c.spool != nil {
+		if err := c.spool.Close(); err != nil {
+			log.Printf("Failed to close spool: %v", err)
+		}
+	}
+
 	return nil
 }
 
+// collectLoop runs every registered source on its own ticker, so a
+// collector's per-source Interval config actually takes effect instead of
+// every source being polled on the same global cadence. It returns once
+// every source's goroutine has stopped, which happens when ctx is done.
 func (c *Collector) collectLoop(ctx context.Context) {
-	ticker := time.NewTicker(c.interval)
+	var wg sync.WaitGroup
+	for _, rs := range c.sources {
+		wg.Add(1)
+		go func(rs registeredSource) {
+			defer wg.Done()
+			c.collectSource(ctx, rs)
+		}(rs)
+	}
+	wg.Wait()
+}
+
+func (c *Collector) collectSource(ctx context.Context, rs registeredSource) {
+	ticker := time.NewTicker(rs.interval)
 	defer ticker.Stop()
 
 	for {
@@ -80,115 +208,61 @@ func (c *Collector) collectLoop(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			c.collectMetrics()
+			if !rs.cfg.Enabled {
+				continue
+			}
+			if err := rs.source.Read(ctx, c.rawBuffer); err != nil {
+				log.Printf("%s collector: %v", rs.source.Name(), err)
+			}
 		}
 	}
 }
 
-func (c *Collector) collectMetrics() {
-	labels := map[string]string{
-		"collector": "glyph-metrics",
-		"runtime":   runtime.Version(),
-	}
-
-	if c.enableCPU {
-		c.collectCPUMetrics(labels)
-	}
-	if c.enableMem {
-		c.collectMemoryMetrics(labels)
-	}
-	if c.enableNet {
-		c.collectNetworkMetrics(labels)
-	}
-	if c.enableDisk {
-		c.collectDiskMetrics(labels)
-	}
-}
-
-func (c *Collector) collectCPUMetrics(labels map[string]string) {
-	percentages, err := cpu.Percent(0, true)
-	if err == nil {
-		for i, pct := range percentages {
-			cpuLabels := copyLabels(labels)
-			cpuLabels["cpu"] = fmt.Sprintf("cpu%d", i)
-			
-			c.buffer <- protocol.CreateMetric(
-				"cpu_usage_percent",
-				pct,
-				protocol.MetricTypeCPU,
-				c.sourceID,
-				cpuLabels,
-			)
+// aggregateLoop sits between collectLoop and sendLoop. With aggregation
+// disabled it's a pass-through; with it enabled, raw samples are folded
+// into c.aggregator and only the periodic derived min/max/avg/median/sum
+// metrics (plus raw samples too, if keepRaw is set) reach the sink.
+func (c *Collector) aggregateLoop(ctx context.Context) {
+	if c.aggregator == nil {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case metric, ok := <-c.rawBuffer:
+				if !ok {
+					return
+				}
+				c.buffer <- metric
+			}
 		}
 	}
-}
 
-func (c *Collector) collectMemoryMetrics(labels map[string]string) {
-	vmStat, err := mem.VirtualMemory()
-	if err == nil {
-		c.buffer <- protocol.CreateMetric(
-			"memory_usage_percent",
-			vmStat.UsedPercent,
-			protocol.MetricTypeMemory,
-			c.sourceID,
-			labels,
-		)
-		
-		c.buffer <- protocol.CreateMetric(
-			"memory_used_bytes",
-			float64(vmStat.Used),
-			protocol.MetricTypeMemory,
-			c.sourceID,
-			labels,
-		)
-	}
-}
-
-func (c *Collector) collectNetworkMetrics(labels map[string]string) {
-	ioCounters, err := net.IOCounters(true)
-	if err == nil {
-		for _, counter := range ioCounters {
-			netLabels := copyLabels(labels)
-			netLabels["interface"] = counter.Name
-			
-			c.buffer <- protocol.CreateMetric(
-				"network_bytes_sent",
-				float64(counter.BytesSent),
-				protocol.MetricTypeNetwork,
-				c.sourceID,
-				netLabels,
-			)
-			
-			c.buffer <- protocol.CreateMetric(
-				"network_bytes_recv",
-				float64(counter.BytesRecv),
-				protocol.MetricTypeNetwork,
-				c.sourceID,
-				netLabels,
-			)
+	ticker := time.NewTicker(c.aggWindow)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.flushAggregator()
+			return
+		case metric, ok := <-c.rawBuffer:
+			if !ok {
+				c.flushAggregator()
+				return
+			}
+			if c.keepRaw {
+				c.buffer <- metric
+			}
+			c.aggregator.Add(metric)
+		case <-ticker.C:
+			c.flushAggregator()
 		}
 	}
 }
 
-func (c *Collector) collectDiskMetrics(labels map[string]string) {
-	partitions, err := disk.Partitions(false)
-	if err == nil {
-		for _, partition := range partitions {
-			usage, err := disk.Usage(partition.Mountpoint)
-			if err == nil {
-				diskLabels := copyLabels(labels)
-				diskLabels["device"] = partition.Device
-				diskLabels["mountpoint"] = partition.Mountpoint
-				
-				c.buffer <- protocol.CreateMetric(
-					"disk_usage_percent",
-					usage.UsedPercent,
-					protocol.MetricTypeDisk,
-					c.sourceID,
-					diskLabels,
-				)
-			}
-		}
+func (c *Collector) flushAggregator() {
+	for _, m := range c.aggregator.Flush() {
+		c.buffer <- m
 	}
 }
 
@@ -211,6 +285,7 @@ func (c *Collector) sendLoop(ctx context.Context) {
 				}
 				return
 			}
+			c.registry.Store(fingerprint(metric.MetricName, metric.Labels), metric)
 			batch = append(batch, metric)
 			if len(batch) >= c.batchSize {
 				c.sendBatch(batch)
@@ -226,9 +301,108 @@ func (c *Collector) sendLoop(ctx context.Context) {
 }
 
 func (c *Collector) sendBatch(batch []protocol.MetricData) {
-	if err := c.handler.SendMetrics(batch); err != nil {
+	if err := c.sink.Write(batch); err != nil {
 		log.Printf("Failed to send metrics batch: %v", err)
+		c.spoolBatch(undeliveredMetrics(batch, err))
+	}
+}
+
+// undeliveredMetrics returns just the metrics a Sink didn't manage to
+// deliver. Most Sink.Write implementations send a batch as a single unit,
+// so a plain error means none of it went through and the whole batch is
+// undelivered. A Sink that splits a batch internally (Handler, routing by
+// tenant) instead reports exactly which metrics failed via
+// PartialWriteError, so only those are re-spooled.
+func undeliveredMetrics(batch []protocol.MetricData, err error) []protocol.MetricData {
+	var partial *protocol.PartialWriteError
+	if errors.As(err, &partial) {
+		return partial.Failed
+	}
+	return batch
+}
+
+// spoolBatch is a no-op unless SpoolDir is configured. It JSON-encodes batch
+// so replaySpooledBatch can decode it back into the same []protocol.MetricData
+// shape regardless of which sink eventually replays it.
+func (c *Collector) spoolBatch(batch []protocol.MetricData) {
+	if c.spool == nil {
+		return
+	}
+
+	data, err := json.Marshal(batch)
+	if err != nil {
+		log.Printf("Failed to marshal batch for spool: %v", err)
+		return
+	}
+
+	if err := c.spool.Append(data); err != nil {
+		log.Printf("Failed to spool batch: %v", err)
+	}
+}
+
+// replaySpooledBatch decodes a spooled record back into a batch and hands it
+// to the sink, the same path replayLoop and the startup replay both use.
+func (c *Collector) replaySpooledBatch(data []byte) error {
+	var batch []protocol.MetricData
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return fmt.Errorf("unmarshal spooled batch: %w", err)
 	}
+	return c.sink.Write(batch)
+}
+
+// replayLoop keeps draining the spool with exponential backoff between
+// attempts while the sink is unreachable, and polls at a fixed interval
+// once a drain succeeds, since there's nothing left to back off from.
+func (c *Collector) replayLoop(ctx context.Context) {
+	for {
+		b := backoff.NewExponentialBackOff()
+		b.MaxElapsedTime = 0
+
+		err := backoff.Retry(func() error {
+			return c.spool.Replay(ctx, c.replaySpooledBatch)
+		}, backoff.WithContext(b, ctx))
+
+		if err != nil {
+			// Only ctx cancellation can end Retry with MaxElapsedTime == 0.
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(spoolReplayPollInterval):
+		}
+	}
+}
+
+// Snapshot returns the most recently observed sample for every
+// (metric_name, labels) fingerprint. It's read by pull-based consumers like
+// the Prometheus exporter without disturbing the push sendLoop.
+func (c *Collector) Snapshot() []protocol.MetricData {
+	samples := make([]protocol.MetricData, 0)
+	c.registry.Range(func(_, value interface{}) bool {
+		samples = append(samples, value.(protocol.MetricData))
+		return true
+	})
+	return samples
+}
+
+func fingerprint(name string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		b.WriteByte('|')
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(labels[k])
+	}
+	return b.String()
 }
 
 func copyLabels(labels map[string]string) map[string]string {
@@ -238,3 +412,13 @@ func copyLabels(labels map[string]string) map[string]string {
 	}
 	return copied
 }
+
+// mergeLabels copies the shared labels and overlays any static tags
+// configured for a specific collector.
+func mergeLabels(labels map[string]string, tags map[string]string) map[string]string {
+	merged := copyLabels(labels)
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return merged
+}
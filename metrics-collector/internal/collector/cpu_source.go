@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"glyph-foundry/metrics-collector/internal/config"
+	"glyph-foundry/metrics-collector/internal/protocol"
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// CPUSource reports per-core CPU utilization via gopsutil.
+type CPUSource struct {
+	cfg      *config.CollectorConfig
+	sourceID string
+	labels   map[string]string
+}
+
+func (s *CPUSource) Name() string { return "cpu" }
+
+func (s *CPUSource) Init(init SourceInit) error {
+	s.cfg = init.Config
+	s.sourceID = init.SourceID
+	s.labels = init.SharedLabels
+	return nil
+}
+
+func (s *CPUSource) Read(ctx context.Context, out chan<- protocol.MetricData) error {
+	if s.cfg.ExcludesMetric("cpu_usage_percent") {
+		return nil
+	}
+
+	percentages, err := cpu.Percent(0, true)
+	if err != nil {
+		return fmt.Errorf("read cpu percentages: %w", err)
+	}
+
+	for i, pct := range percentages {
+		labels := mergeLabels(s.labels, s.cfg.Tags)
+		labels["cpu"] = fmt.Sprintf("cpu%d", i)
+
+		out <- protocol.CreateMetric(
+			"cpu_usage_percent",
+			pct,
+			protocol.MetricTypeCPU,
+			s.sourceID,
+			labels,
+		)
+	}
+	return nil
+}
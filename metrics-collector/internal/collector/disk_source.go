@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"glyph-foundry/metrics-collector/internal/config"
+	"glyph-foundry/metrics-collector/internal/protocol"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// DiskSource reports per-partition disk usage via gopsutil.
+type DiskSource struct {
+	cfg      *config.CollectorConfig
+	sourceID string
+	labels   map[string]string
+}
+
+func (s *DiskSource) Name() string { return "disk" }
+
+func (s *DiskSource) Init(init SourceInit) error {
+	s.cfg = init.Config
+	s.sourceID = init.SourceID
+	s.labels = init.SharedLabels
+	return nil
+}
+
+func (s *DiskSource) Read(ctx context.Context, out chan<- protocol.MetricData) error {
+	if s.cfg.ExcludesMetric("disk_usage_percent") {
+		return nil
+	}
+
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		return fmt.Errorf("read disk partitions: %w", err)
+	}
+
+	for _, partition := range partitions {
+		if !s.cfg.IncludesDevice(partition.Device) {
+			continue
+		}
+
+		usage, err := disk.Usage(partition.Mountpoint)
+		if err != nil {
+			continue
+		}
+
+		labels := mergeLabels(s.labels, s.cfg.Tags)
+		labels["device"] = partition.Device
+		labels["mountpoint"] = partition.Mountpoint
+
+		out <- protocol.CreateMetric(
+			"disk_usage_percent",
+			usage.UsedPercent,
+			protocol.MetricTypeDisk,
+			s.sourceID,
+			labels,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"glyph-foundry/metrics-collector/internal/config"
+	"glyph-foundry/metrics-collector/internal/protocol"
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+// GPUSource reports per-GPU utilization, memory, temperature, and power via
+// NVML. When enabled, Init fails if the NVIDIA driver can't be found, so a host without a
+// GPU surfaces that at startup rather than as silently-empty metrics; the
+// collector logs the failure and simply leaves this source unregistered.
+type GPUSource struct {
+	cfg      *config.CollectorConfig
+	sourceID string
+	labels   map[string]string
+}
+
+func (s *GPUSource) Name() string { return "gpu" }
+
+func (s *GPUSource) Init(init SourceInit) error {
+	s.cfg = init.Config
+	s.sourceID = init.SourceID
+	s.labels = init.SharedLabels
+
+	if !init.Config.Enabled {
+		return nil
+	}
+
+	if ret := nvml.Init(); ret != nvml.SUCCESS {
+		return fmt.Errorf("nvml init: %v", nvml.ErrorString(ret))
+	}
+	return nil
+}
+
+func (s *GPUSource) Read(ctx context.Context, out chan<- protocol.MetricData) error {
+	count, ret := nvml.DeviceGetCount()
+	if ret != nvml.SUCCESS {
+		return fmt.Errorf("nvml device count: %v", nvml.ErrorString(ret))
+	}
+
+	for i := 0; i < count; i++ {
+		device, ret := nvml.DeviceGetHandleByIndex(i)
+		if ret != nvml.SUCCESS {
+			continue
+		}
+
+		gpuName := fmt.Sprintf("gpu%d", i)
+		if !s.cfg.IncludesDevice(gpuName) {
+			continue
+		}
+
+		labels := mergeLabels(s.labels, s.cfg.Tags)
+		labels["gpu"] = gpuName
+
+		if util, ret := device.GetUtilizationRates(); ret == nvml.SUCCESS && !s.cfg.ExcludesMetric("gpu_utilization_percent") {
+			out <- protocol.CreateMetric("gpu_utilization_percent", float64(util.Gpu), protocol.MetricTypeGPU, s.sourceID, labels)
+		}
+		if memInfo, ret := device.GetMemoryInfo(); ret == nvml.SUCCESS && !s.cfg.ExcludesMetric("gpu_memory_used_bytes") {
+			out <- protocol.CreateMetric("gpu_memory_used_bytes", float64(memInfo.Used), protocol.MetricTypeGPU, s.sourceID, labels)
+		}
+		if temp, ret := device.GetTemperature(nvml.TEMPERATURE_GPU); ret == nvml.SUCCESS && !s.cfg.ExcludesMetric("gpu_temperature_celsius") {
+			out <- protocol.CreateMetric("gpu_temperature_celsius", float64(temp), protocol.MetricTypeGPU, s.sourceID, labels)
+		}
+		if power, ret := device.GetPowerUsage(); ret == nvml.SUCCESS && !s.cfg.ExcludesMetric("gpu_power_watts") {
+			out <- protocol.CreateMetric("gpu_power_watts", float64(power)/1000, protocol.MetricTypeGPU, s.sourceID, labels)
+		}
+	}
+
+	return nil
+}
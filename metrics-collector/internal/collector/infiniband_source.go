@@ -0,0 +1,97 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"glyph-foundry/metrics-collector/internal/config"
+	"glyph-foundry/metrics-collector/internal/protocol"
+)
+
+const infinibandSysfsRoot = "/sys/class/infiniband"
+
+// InfinibandSource reads raw HCA port counters from sysfs and derives
+// ib_total (bytes) and ib_total_pkts (packets) as the sum of xmit and rcv
+// traffic per port -- aggregates the raw counters don't provide on their
+// own. Hosts without Infiniband hardware simply have no counters to read.
+type InfinibandSource struct {
+	cfg      *config.CollectorConfig
+	sourceID string
+	labels   map[string]string
+}
+
+func (s *InfinibandSource) Name() string { return "infiniband" }
+
+func (s *InfinibandSource) Init(init SourceInit) error {
+	s.cfg = init.Config
+	s.sourceID = init.SourceID
+	s.labels = init.SharedLabels
+	return nil
+}
+
+func (s *InfinibandSource) Read(ctx context.Context, out chan<- protocol.MetricData) error {
+	devices, err := os.ReadDir(infinibandSysfsRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("list infiniband devices: %w", err)
+	}
+
+	for _, device := range devices {
+		if !s.cfg.IncludesDevice(device.Name()) {
+			continue
+		}
+
+		portsRoot := filepath.Join(infinibandSysfsRoot, device.Name(), "ports")
+		ports, err := os.ReadDir(portsRoot)
+		if err != nil {
+			continue
+		}
+
+		for _, port := range ports {
+			counters := filepath.Join(portsRoot, port.Name(), "counters")
+
+			// port_xmit_data/port_rcv_data are in units of 4 octets per the
+			// IBTA PortCounters spec, not raw bytes -- multiply by 4 before
+			// summing, the same conversion perfquery applies.
+			xmitBytes := readInfinibandCounter(counters, "port_xmit_data") * 4
+			rcvBytes := readInfinibandCounter(counters, "port_rcv_data") * 4
+			xmitPkts := readInfinibandCounter(counters, "port_xmit_packets")
+			rcvPkts := readInfinibandCounter(counters, "port_rcv_packets")
+
+			labels := mergeLabels(s.labels, s.cfg.Tags)
+			labels["device"] = device.Name()
+			labels["port"] = port.Name()
+
+			if !s.cfg.ExcludesMetric("ib_total") {
+				out <- protocol.CreateMetric("ib_total", xmitBytes+rcvBytes, protocol.MetricTypeInfiniband, s.sourceID, labels)
+			}
+			if !s.cfg.ExcludesMetric("ib_total_pkts") {
+				out <- protocol.CreateMetric("ib_total_pkts", xmitPkts+rcvPkts, protocol.MetricTypeInfiniband, s.sourceID, labels)
+			}
+		}
+	}
+
+	return nil
+}
+
+// readInfinibandCounter reads a single sysfs counter file, returning 0 if
+// it's missing or unreadable -- which counters exist varies by HCA
+// vendor/firmware, and a missing one shouldn't fail the whole read.
+func readInfinibandCounter(countersDir, name string) float64 {
+	data, err := os.ReadFile(filepath.Join(countersDir, name))
+	if err != nil {
+		return 0
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
@@ -0,0 +1,57 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"glyph-foundry/metrics-collector/internal/config"
+	"glyph-foundry/metrics-collector/internal/protocol"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// MemSource reports system virtual memory usage via gopsutil.
+type MemSource struct {
+	cfg      *config.CollectorConfig
+	sourceID string
+	labels   map[string]string
+}
+
+func (s *MemSource) Name() string { return "mem" }
+
+func (s *MemSource) Init(init SourceInit) error {
+	s.cfg = init.Config
+	s.sourceID = init.SourceID
+	s.labels = init.SharedLabels
+	return nil
+}
+
+func (s *MemSource) Read(ctx context.Context, out chan<- protocol.MetricData) error {
+	vmStat, err := mem.VirtualMemory()
+	if err != nil {
+		return fmt.Errorf("read virtual memory: %w", err)
+	}
+
+	labels := mergeLabels(s.labels, s.cfg.Tags)
+
+	if !s.cfg.ExcludesMetric("memory_usage_percent") {
+		out <- protocol.CreateMetric(
+			"memory_usage_percent",
+			vmStat.UsedPercent,
+			protocol.MetricTypeMemory,
+			s.sourceID,
+			labels,
+		)
+	}
+
+	if !s.cfg.ExcludesMetric("memory_used_bytes") {
+		out <- protocol.CreateMetric(
+			"memory_used_bytes",
+			float64(vmStat.Used),
+			protocol.MetricTypeMemory,
+			s.sourceID,
+			labels,
+		)
+	}
+
+	return nil
+}
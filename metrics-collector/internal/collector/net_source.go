@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"glyph-foundry/metrics-collector/internal/config"
+	"glyph-foundry/metrics-collector/internal/protocol"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// NetSource reports per-interface network counters via gopsutil.
+type NetSource struct {
+	cfg      *config.CollectorConfig
+	sourceID string
+	labels   map[string]string
+}
+
+func (s *NetSource) Name() string { return "net" }
+
+func (s *NetSource) Init(init SourceInit) error {
+	s.cfg = init.Config
+	s.sourceID = init.SourceID
+	s.labels = init.SharedLabels
+	return nil
+}
+
+func (s *NetSource) Read(ctx context.Context, out chan<- protocol.MetricData) error {
+	ioCounters, err := net.IOCounters(true)
+	if err != nil {
+		return fmt.Errorf("read network counters: %w", err)
+	}
+
+	for _, counter := range ioCounters {
+		if !s.cfg.IncludesDevice(counter.Name) {
+			continue
+		}
+
+		labels := mergeLabels(s.labels, s.cfg.Tags)
+		labels["interface"] = counter.Name
+
+		if !s.cfg.ExcludesMetric("network_bytes_sent") {
+			out <- protocol.CreateMetric(
+				"network_bytes_sent",
+				float64(counter.BytesSent),
+				protocol.MetricTypeNetwork,
+				s.sourceID,
+				labels,
+			)
+		}
+
+		if !s.cfg.ExcludesMetric("network_bytes_recv") {
+			out <- protocol.CreateMetric(
+				"network_bytes_recv",
+				float64(counter.BytesRecv),
+				protocol.MetricTypeNetwork,
+				s.sourceID,
+				labels,
+			)
+		}
+	}
+
+	return nil
+}
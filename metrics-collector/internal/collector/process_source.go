@@ -0,0 +1,136 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"glyph-foundry/metrics-collector/internal/config"
+	"glyph-foundry/metrics-collector/internal/protocol"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const defaultProcessTopN = 5
+
+// processSample is one process's ranked CPU/RSS reading for a single Read.
+type processSample struct {
+	pid  int32
+	name string
+	cpu  float64
+	rss  uint64
+}
+
+// processCPUTimes is a pid's cumulative CPU time as of a given instant, used
+// to diff successive Read calls into an interval CPU percentage rather than
+// gopsutil's own CPUPercentWithContext, which divides cumulative CPU time by
+// wall-clock age since process start -- a lifetime average that barely
+// moves tick to tick for a long-lived process.
+type processCPUTimes struct {
+	total float64
+	at    time.Time
+}
+
+// ProcessSource reports the top-N processes by CPU and by RSS, using
+// gopsutil. TopN comes from the collector's config block and falls back to
+// defaultProcessTopN when unset.
+type ProcessSource struct {
+	cfg      *config.CollectorConfig
+	sourceID string
+	labels   map[string]string
+
+	mu        sync.Mutex
+	prevTimes map[int32]processCPUTimes
+}
+
+func (s *ProcessSource) Name() string { return "process" }
+
+func (s *ProcessSource) Init(init SourceInit) error {
+	s.cfg = init.Config
+	s.sourceID = init.SourceID
+	s.labels = init.SharedLabels
+	s.prevTimes = make(map[int32]processCPUTimes)
+	return nil
+}
+
+func (s *ProcessSource) Read(ctx context.Context, out chan<- protocol.MetricData) error {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("list processes: %w", err)
+	}
+
+	topN := s.cfg.TopN
+	if topN <= 0 {
+		topN = defaultProcessTopN
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	prevTimes := s.prevTimes
+	s.mu.Unlock()
+	nextTimes := make(map[int32]processCPUTimes, len(procs))
+
+	samples := make([]processSample, 0, len(procs))
+	for _, p := range procs {
+		name, err := p.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		times, err := p.TimesWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		memInfo, err := p.MemoryInfoWithContext(ctx)
+		if err != nil {
+			continue
+		}
+
+		total := times.User + times.System
+		nextTimes[p.Pid] = processCPUTimes{total: total, at: now}
+
+		var cpuPct float64
+		if prev, ok := prevTimes[p.Pid]; ok {
+			if elapsed := now.Sub(prev.at).Seconds(); elapsed > 0 {
+				cpuPct = ((total - prev.total) / elapsed) * 100
+			}
+		}
+
+		samples = append(samples, processSample{pid: p.Pid, name: name, cpu: cpuPct, rss: memInfo.RSS})
+	}
+
+	s.mu.Lock()
+	s.prevTimes = nextTimes
+	s.mu.Unlock()
+
+	if !s.cfg.ExcludesMetric("process_cpu_percent") {
+		byCPU := append([]processSample(nil), samples...)
+		sort.Slice(byCPU, func(i, j int) bool { return byCPU[i].cpu > byCPU[j].cpu })
+		s.emit(out, "process_cpu_percent", firstNProcesses(byCPU, topN), func(p processSample) float64 { return p.cpu })
+	}
+
+	if !s.cfg.ExcludesMetric("process_rss_bytes") {
+		byRSS := append([]processSample(nil), samples...)
+		sort.Slice(byRSS, func(i, j int) bool { return byRSS[i].rss > byRSS[j].rss })
+		s.emit(out, "process_rss_bytes", firstNProcesses(byRSS, topN), func(p processSample) float64 { return float64(p.rss) })
+	}
+
+	return nil
+}
+
+func (s *ProcessSource) emit(out chan<- protocol.MetricData, metricName string, samples []processSample, value func(processSample) float64) {
+	for _, p := range samples {
+		labels := mergeLabels(s.labels, s.cfg.Tags)
+		labels["pid"] = fmt.Sprintf("%d", p.pid)
+		labels["process"] = p.name
+
+		out <- protocol.CreateMetric(metricName, value(p), protocol.MetricTypeProcess, s.sourceID, labels)
+	}
+}
+
+func firstNProcesses(samples []processSample, n int) []processSample {
+	if n > len(samples) {
+		n = len(samples)
+	}
+	return samples[:n]
+}
@@ -0,0 +1,36 @@
+package collector
+
+import (
+	"context"
+
+	"glyph-foundry/metrics-collector/internal/config"
+	"glyph-foundry/metrics-collector/internal/protocol"
+)
+
+// SourceInit bundles what a MetricSource needs before its first Read: its
+// own config block, the source ID to stamp on every metric it emits, and
+// the labels shared across every collector.
+type SourceInit struct {
+	Config       *config.CollectorConfig
+	SourceID     string
+	SharedLabels map[string]string
+}
+
+// MetricSource is a pluggable metric collector. Adding a new source to the
+// collector is a matter of implementing this interface and registering it
+// in Collector.New, rather than growing the monolithic collect loop.
+type MetricSource interface {
+	// Name identifies the source in logs and config file blocks (e.g. "cpu").
+	Name() string
+
+	// Init prepares the source to be read, using its config block. It is
+	// called once at startup for every source, even ones that start
+	// disabled; a source may skip its setup while disabled (GPUSource does
+	// not initialize NVML then).
+	Init(init SourceInit) error
+
+	// Read collects one round of samples and pushes them onto out. A
+	// transient read error should be returned rather than panicking; the
+	// collector logs it and tries again on the next tick.
+	Read(ctx context.Context, out chan<- protocol.MetricData) error
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
+	"log"
 	"os"
+	"regexp"
 	"strconv"
 	"time"
 )
@@ -11,32 +15,189 @@ type Config struct {
 	CollectionInterval time.Duration
 	GlyphAPIURL        string
 	TenantID           string
-	
+
+	// Sink settings
+	SinkType      string
+	SinkTargetURL string
+
+	// mTLS and bearer-token auth for the json sink, plus per-metric-type
+	// tenant routing. All optional; an empty value disables that setting.
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSCAFile     string
+	AuthTokenFile string
+	TenantRoutes  map[string]string
+
+	// Exporter settings
+	ExporterListen string
+
+	// Spool settings. SpoolDir of "" disables spooling entirely, so a
+	// failed sink write is just logged and dropped as before.
+	SpoolDir      string
+	SpoolMaxBytes int64
+
+	// Aggregation settings. AggregationWindow of 0 disables aggregation
+	// entirely, so every raw sample goes straight to the sink as before.
+	AggregationWindow  time.Duration
+	AggregationKeepRaw bool
+
 	// Performance settings
 	MaxMetricsPerBatch int
 	WorkerCount        int
 	BufferSize         int
-	
-	// Protocol settings
-	EnableCPUMetrics     bool
-	EnableMemoryMetrics  bool
-	EnableNetworkMetrics bool
-	EnableDiskMetrics    bool
+
+	// Per-collector settings, overridable via CONFIG_FILE
+	CPU        *CollectorConfig
+	Mem        *CollectorConfig
+	Net        *CollectorConfig
+	Disk       *CollectorConfig
+	Infiniband *CollectorConfig
+	GPU        *CollectorConfig
+	Process    *CollectorConfig
+}
+
+// defaultProcessTopN is how many processes the process collector reports
+// per ranking (CPU, RSS) when top_n isn't set.
+const defaultProcessTopN = 5
+
+// CollectorConfig is the per-collector settings block, mirroring the
+// cc-metric-collector convention where each collector owns its own config
+// schema instead of sharing one flat struct. Env vars still set the
+// top-level defaults; CONFIG_FILE lets operators override individual
+// collectors without rebuilding.
+type CollectorConfig struct {
+	Enabled bool `json:"enabled"`
+
+	// Interval overrides the collector's global CollectionInterval for just
+	// this source, parsed with time.ParseDuration (e.g. "30s"). Empty keeps
+	// the global interval.
+	Interval       string            `json:"interval"`
+	ExcludeMetrics []string          `json:"exclude_metrics"`
+	IncludeDevices []string          `json:"include_devices"`
+	Tags           map[string]string `json:"tags"`
+
+	// TopN is only consulted by the process collector, which ranks
+	// processes by CPU and RSS and reports the top N of each.
+	TopN int `json:"top_n"`
+}
+
+// Init unmarshals a collector-specific JSON block into cfg.
+func (cfg *CollectorConfig) Init(raw json.RawMessage) error {
+	return json.Unmarshal(raw, cfg)
+}
+
+// ExcludesMetric reports whether name is on this collector's exclude list.
+func (cfg *CollectorConfig) ExcludesMetric(name string) bool {
+	for _, excluded := range cfg.ExcludeMetrics {
+		if excluded == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IncludesDevice reports whether device matches this collector's
+// include_devices patterns. An empty pattern list includes everything.
+func (cfg *CollectorConfig) IncludesDevice(device string) bool {
+	if len(cfg.IncludeDevices) == 0 {
+		return true
+	}
+	for _, pattern := range cfg.IncludeDevices {
+		if matched, err := regexp.MatchString(pattern, device); err == nil && matched {
+			return true
+		}
+	}
+	return false
 }
 
 func Load() *Config {
-	return &Config{
-		CollectionInterval:   getDuration("COLLECTION_INTERVAL", 1*time.Second),
-		GlyphAPIURL:         getEnv("GLYPH_API_URL", "http://localhost:8000"),
-		TenantID:            getEnv("TENANT_ID", "metrics-collector"),
-		MaxMetricsPerBatch:  getInt("MAX_METRICS_PER_BATCH", 100),
-		WorkerCount:         getInt("WORKER_COUNT", 4),
-		BufferSize:          getInt("BUFFER_SIZE", 1000),
-		EnableCPUMetrics:    getBool("ENABLE_CPU_METRICS", true),
-		EnableMemoryMetrics: getBool("ENABLE_MEMORY_METRICS", true),
-		EnableNetworkMetrics: getBool("ENABLE_NETWORK_METRICS", true),
-		EnableDiskMetrics:   getBool("ENABLE_DISK_METRICS", true),
+	cfg := &Config{
+		CollectionInterval: getDuration("COLLECTION_INTERVAL", 1*time.Second),
+		GlyphAPIURL:        getEnv("GLYPH_API_URL", "http://localhost:8000"),
+		TenantID:           getEnv("TENANT_ID", "metrics-collector"),
+		SinkType:           getEnv("SINK_TYPE", "json"),
+		SinkTargetURL:      getEnv("SINK_TARGET_URL", getEnv("GLYPH_API_URL", "http://localhost:8000")),
+		TLSCertFile:        getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:         getEnv("TLS_KEY_FILE", ""),
+		TLSCAFile:          getEnv("TLS_CA_FILE", ""),
+		AuthTokenFile:      getEnv("AUTH_TOKEN_FILE", ""),
+		ExporterListen:     getEnv("EXPORTER_LISTEN", ""),
+		SpoolDir:           getEnv("SPOOL_DIR", ""),
+		SpoolMaxBytes:      getInt64("SPOOL_MAX_BYTES", 100*1024*1024),
+		AggregationWindow:  getDuration("AGGREGATION_WINDOW", 0),
+		AggregationKeepRaw: getBool("AGGREGATION_KEEP_RAW", false),
+		MaxMetricsPerBatch: getInt("MAX_METRICS_PER_BATCH", 100),
+		WorkerCount:        getInt("WORKER_COUNT", 4),
+		BufferSize:         getInt("BUFFER_SIZE", 1000),
+		CPU:                &CollectorConfig{Enabled: getBool("ENABLE_CPU_METRICS", true)},
+		Mem:                &CollectorConfig{Enabled: getBool("ENABLE_MEMORY_METRICS", true)},
+		Net:                &CollectorConfig{Enabled: getBool("ENABLE_NETWORK_METRICS", true)},
+		Disk:               &CollectorConfig{Enabled: getBool("ENABLE_DISK_METRICS", true)},
+		Infiniband:         &CollectorConfig{Enabled: getBool("ENABLE_INFINIBAND_METRICS", false)},
+		GPU:                &CollectorConfig{Enabled: getBool("ENABLE_GPU_METRICS", false)},
+		Process: &CollectorConfig{
+			Enabled: getBool("ENABLE_PROCESS_METRICS", false),
+			TopN:    getInt("PROCESS_TOP_N", defaultProcessTopN),
+		},
 	}
+
+	if path := getEnv("CONFIG_FILE", ""); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			log.Printf("Failed to load CONFIG_FILE %s: %v", path, err)
+		}
+	}
+
+	return cfg
+}
+
+// loadFile overlays per-collector blocks from a CONFIG_FILE onto the
+// env-derived defaults. A collector missing from the file keeps its
+// env/default settings untouched.
+func (cfg *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var blocks struct {
+		CPU          json.RawMessage   `json:"cpu"`
+		Mem          json.RawMessage   `json:"mem"`
+		Net          json.RawMessage   `json:"net"`
+		Disk         json.RawMessage   `json:"disk"`
+		Infiniband   json.RawMessage   `json:"infiniband"`
+		GPU          json.RawMessage   `json:"gpu"`
+		Process      json.RawMessage   `json:"process"`
+		TenantRoutes map[string]string `json:"tenant_routes"`
+	}
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if blocks.TenantRoutes != nil {
+		cfg.TenantRoutes = blocks.TenantRoutes
+	}
+
+	for _, block := range []struct {
+		raw    json.RawMessage
+		target *CollectorConfig
+	}{
+		{blocks.CPU, cfg.CPU},
+		{blocks.Mem, cfg.Mem},
+		{blocks.Net, cfg.Net},
+		{blocks.Disk, cfg.Disk},
+		{blocks.Infiniband, cfg.Infiniband},
+		{blocks.GPU, cfg.GPU},
+		{blocks.Process, cfg.Process},
+	} {
+		if block.raw == nil {
+			continue
+		}
+		if err := block.target.Init(block.raw); err != nil {
+			return fmt.Errorf("failed to parse collector config: %w", err)
+		}
+	}
+
+	return nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -64,6 +225,15 @@ func getBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+func getInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
 func getDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if d, err := time.ParseDuration(value); err == nil {
@@ -0,0 +1,97 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"glyph-foundry/metrics-collector/internal/protocol"
+)
+
+// MetricSource is anything that can return the most recent sample for every
+// (metric_name, labels) fingerprint it has collected. *collector.Collector
+// implements this.
+type MetricSource interface {
+	Snapshot() []protocol.MetricData
+}
+
+// Exporter serves the collector's most recent samples for pull-based
+// scraping. Endpoints are grouped the way Minio's Metrics V3 groups its
+// surface: /metrics/v3/system/{cpu,memory,net,disk,infiniband,gpu,process}
+// for each collector, plus a root /metrics/v3 that concatenates every group.
+type Exporter struct {
+	source MetricSource
+	server *http.Server
+}
+
+// New builds an Exporter that will listen on listen once Start is called.
+func New(source MetricSource, listen string) *Exporter {
+	e := &Exporter{source: source}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics/v3/system/cpu", e.handleGroup(protocol.MetricTypeCPU))
+	mux.HandleFunc("/metrics/v3/system/memory", e.handleGroup(protocol.MetricTypeMemory))
+	mux.HandleFunc("/metrics/v3/system/net", e.handleGroup(protocol.MetricTypeNetwork))
+	mux.HandleFunc("/metrics/v3/system/disk", e.handleGroup(protocol.MetricTypeDisk))
+	mux.HandleFunc("/metrics/v3/system/infiniband", e.handleGroup(protocol.MetricTypeInfiniband))
+	mux.HandleFunc("/metrics/v3/system/gpu", e.handleGroup(protocol.MetricTypeGPU))
+	mux.HandleFunc("/metrics/v3/system/process", e.handleGroup(protocol.MetricTypeProcess))
+	mux.HandleFunc("/metrics/v3", e.handleAll)
+	mux.HandleFunc("/metrics", e.handleAll)
+
+	e.server = &http.Server{
+		Addr:    listen,
+		Handler: mux,
+	}
+	return e
+}
+
+// Start blocks serving scrape requests until the server is shut down.
+func (e *Exporter) Start() error {
+	return e.server.ListenAndServe()
+}
+
+// Shutdown gracefully stops the embedded HTTP server.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	return e.server.Shutdown(ctx)
+}
+
+func (e *Exporter) handleGroup(metricType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		for _, m := range e.source.Snapshot() {
+			if m.MetricType == metricType {
+				writeLine(w, m)
+			}
+		}
+	}
+}
+
+func (e *Exporter) handleAll(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	for _, m := range e.source.Snapshot() {
+		writeLine(w, m)
+	}
+}
+
+func writeLine(w http.ResponseWriter, m protocol.MetricData) {
+	var b strings.Builder
+	b.WriteString(m.MetricName)
+
+	if len(m.Labels) > 0 {
+		b.WriteByte('{')
+		first := true
+		for k, v := range m.Labels {
+			if !first {
+				b.WriteByte(',')
+			}
+			first = false
+			fmt.Fprintf(&b, "%s=%q", k, v)
+		}
+		b.WriteByte('}')
+	}
+
+	fmt.Fprintf(&b, " %v %d\n", m.MetricValue, m.Timestamp.UnixMilli())
+	w.Write([]byte(b.String()))
+}
@@ -2,34 +2,181 @@ package protocol
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// tokenRefreshInterval is how often a Handler with AuthTokenFile set
+// re-reads the token off disk. Matches the rotation cadence Kubernetes
+// expects consumers of a projected service account token to poll at.
+const tokenRefreshInterval = 5 * time.Minute
+
+// HandlerConfig configures a Handler's transport (mTLS client certs plus a
+// CA bundle for the server cert), authentication (a bearer token refreshed
+// from disk), and multi-tenant routing (per-metric-type tenant overrides).
+type HandlerConfig struct {
+	APIURL   string
+	TenantID string
+
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSCAFile   string
+
+	AuthTokenFile string
+
+	// TenantRoutes maps a MetricType constant to the tenant ID batches of
+	// that type should be attributed to, overriding TenantID for just
+	// those metrics.
+	TenantRoutes map[string]string
+}
+
+// Handler is the Sink implementation that POSTs metric batches to the Glyph
+// API as JSON. It is the original, still-default wire format.
 type Handler struct {
-	apiURL   string
-	tenantID string
-	client   *http.Client
+	apiURL       string
+	tenantID     string
+	tenantRoutes map[string]string
+	client       *http.Client
+
+	tokenFile string
+	token     atomic.Value // string
 }
 
-func NewHandler(apiURL, tenantID string) *Handler {
-	return &Handler{
-		apiURL:   apiURL,
-		tenantID: tenantID,
+func NewHandler(cfg HandlerConfig) (*Handler, error) {
+	transport := &http.Transport{}
+
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" || cfg.TLSCAFile != "" {
+		tlsConfig, err := buildTLSConfig(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build TLS config: %w", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	h := &Handler{
+		apiURL:       cfg.APIURL,
+		tenantID:     cfg.TenantID,
+		tenantRoutes: cfg.TenantRoutes,
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: transport,
 		},
+		tokenFile: cfg.AuthTokenFile,
+	}
+
+	if h.tokenFile != "" {
+		if err := h.refreshToken(); err != nil {
+			return nil, fmt.Errorf("failed to load auth token: %w", err)
+		}
+		go h.refreshTokenLoop()
+	}
+
+	return h, nil
+}
+
+func buildTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client keypair: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile != "" {
+		caCert, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates parsed from %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// refreshTokenLoop re-reads tokenFile on an interval rather than once at
+// startup, since a projected service account token is rotated on disk by
+// the kubelet rather than pushed to the process.
+func (h *Handler) refreshTokenLoop() {
+	ticker := time.NewTicker(tokenRefreshInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := h.refreshToken(); err != nil {
+			log.Printf("Failed to refresh auth token: %v", err)
+		}
+	}
+}
+
+func (h *Handler) refreshToken() error {
+	data, err := os.ReadFile(h.tokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read auth token file: %w", err)
+	}
+	h.token.Store(strings.TrimSpace(string(data)))
+	return nil
+}
+
+// tenantFor returns the tenant ID a metric of metricType should be
+// attributed to: its TenantRoutes override if one exists, else the
+// Handler's default TenantID.
+func (h *Handler) tenantFor(metricType string) string {
+	if tenantID, ok := h.tenantRoutes[metricType]; ok {
+		return tenantID
 	}
+	return h.tenantID
 }
 
-func (h *Handler) SendMetrics(metrics []MetricData) error {
+// Write implements Sink by POSTing the batch as JSON to the Glyph API,
+// split into one request per destination tenant so a single mixed batch
+// still respects per-metric-type tenant routing. Every group is attempted
+// even if an earlier one fails, and only the metrics from groups that
+// didn't go through are reported back via PartialWriteError, so a caller
+// spooling on failure doesn't re-send metrics that already succeeded.
+func (h *Handler) Write(metrics []MetricData) error {
 	if len(metrics) == 0 {
 		return nil
 	}
 
+	groups := make(map[string][]MetricData)
+	for _, m := range metrics {
+		tenantID := h.tenantFor(m.MetricType)
+		groups[tenantID] = append(groups[tenantID], m)
+	}
+
+	var failed []MetricData
+	var firstErr error
+	for tenantID, batch := range groups {
+		if err := h.send(tenantID, batch); err != nil {
+			failed = append(failed, batch...)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return &PartialWriteError{Failed: failed, Err: firstErr}
+	}
+
+	return nil
+}
+
+func (h *Handler) send(tenantID string, metrics []MetricData) error {
 	jsonData, err := json.Marshal(metrics)
 	if err != nil {
 		return fmt.Errorf("failed to marshal metrics: %w", err)
@@ -41,7 +188,10 @@ func (h *Handler) SendMetrics(metrics []MetricData) error {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Tenant-Id", h.tenantID)
+	req.Header.Set("X-Tenant-Id", tenantID)
+	if tok, ok := h.token.Load().(string); ok && tok != "" {
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
 
 	resp, err := h.client.Do(req)
 	if err != nil {
@@ -0,0 +1,95 @@
+package protocol
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/line-protocol/v2/lineprotocol"
+)
+
+// InfluxSink writes metric batches to an InfluxDB line-protocol v2 compatible
+// HTTP endpoint (InfluxDB, Telegraf's http_listener_v2, VictoriaMetrics'
+// /write endpoint). Line-protocol encoding is dramatically cheaper than JSON
+// marshaling per metric, which matters at 1-second collection intervals
+// across many hosts.
+type InfluxSink struct {
+	writeURL string
+	tenantID string
+	client   *http.Client
+}
+
+func NewInfluxSink(writeURL, tenantID string) *InfluxSink {
+	return &InfluxSink{
+		writeURL: writeURL,
+		tenantID: tenantID,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (s *InfluxSink) Write(metrics []MetricData) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	var enc lineprotocol.Encoder
+	enc.SetPrecision(lineprotocol.Nanosecond)
+
+	for _, m := range metrics {
+		// A NaN/Inf sample from any current or future MetricSource shouldn't
+		// be able to take down the whole collector, so skip just that one
+		// field rather than using the panicking MustNewValue.
+		value, ok := lineprotocol.NewValue(m.MetricValue)
+		if !ok {
+			log.Printf("Skipping metric %s: value %v is not encodable as line protocol", m.MetricName, m.MetricValue)
+			continue
+		}
+
+		// The encoder requires tag keys in lexical order.
+		tags := make(map[string]string, len(m.Labels)+2)
+		for k, v := range m.Labels {
+			tags[k] = v
+		}
+		tags["metric_name"] = m.MetricName
+		tags["source_id"] = m.SourceID
+		keys := make([]string, 0, len(tags))
+		for k := range tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		enc.StartLine(m.MetricType)
+		for _, k := range keys {
+			enc.AddTag(k, tags[k])
+		}
+		enc.AddField("value", value)
+		enc.EndLine(m.Timestamp)
+		if err := enc.Err(); err != nil {
+			return fmt.Errorf("failed to encode metric %s: %w", m.MetricName, err)
+		}
+	}
+
+	req, err := http.NewRequest("POST", s.writeURL, strings.NewReader(string(enc.Bytes())))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("X-Tenant-Id", s.tenantID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("influx write returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
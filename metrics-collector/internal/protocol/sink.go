@@ -0,0 +1,83 @@
+package protocol
+
+import "fmt"
+
+// Sink is the destination a Collector writes metric batches to.
+// Implementations translate an in-memory batch into a specific wire format
+// and deliver it to a downstream system (the Glyph API, an InfluxDB-compatible
+// line-protocol endpoint, etc), so the collector itself stays format-agnostic.
+type Sink interface {
+	Write(metrics []MetricData) error
+}
+
+// PartialWriteError is returned by a Sink whose Write splits a batch
+// internally (e.g. Handler routing by tenant) when any of the split groups
+// failed to send, whether some or all of them. Failed holds just the metrics that weren't
+// delivered, so a caller that spools on error -- like the collector's
+// spool fallback -- doesn't re-queue metrics that already made it through.
+type PartialWriteError struct {
+	Failed []MetricData
+	Err    error
+}
+
+func (e *PartialWriteError) Error() string {
+	return fmt.Sprintf("partial write failure (%d of the batch's metrics undelivered): %v", len(e.Failed), e.Err)
+}
+
+func (e *PartialWriteError) Unwrap() error {
+	return e.Err
+}
+
+// SinkConfig bundles every setting NewSink needs to construct any sink type.
+// Fields specific to one sink type (the mTLS, auth, and tenant-routing
+// settings, which only Handler uses) are simply ignored by the others.
+type SinkConfig struct {
+	SinkType  string
+	TargetURL string
+	TenantID  string
+
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSCAFile   string
+
+	AuthTokenFile string
+	TenantRoutes  map[string]string
+}
+
+// NewSink builds the Sink selected by cfg.SinkType.
+//
+// "prometheus" intentionally returns a no-op sink: Prometheus scraping is
+// pull-based and is served by the internal/exporter package reading from the
+// collector's own metric registry, not by pushing batches anywhere.
+func NewSink(cfg SinkConfig) (Sink, error) {
+	switch cfg.SinkType {
+	case "", "json":
+		return NewHandler(HandlerConfig{
+			APIURL:        cfg.TargetURL,
+			TenantID:      cfg.TenantID,
+			TLSCertFile:   cfg.TLSCertFile,
+			TLSKeyFile:    cfg.TLSKeyFile,
+			TLSCAFile:     cfg.TLSCAFile,
+			AuthTokenFile: cfg.AuthTokenFile,
+			TenantRoutes:  cfg.TenantRoutes,
+		})
+	case "influx":
+		return NewInfluxSink(cfg.TargetURL, cfg.TenantID), nil
+	case "prometheus":
+		return NewNoopSink(), nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", cfg.SinkType)
+	}
+}
+
+// NoopSink discards every batch. It backs SINK_TYPE=prometheus, where metrics
+// are exposed for scraping rather than pushed downstream.
+type NoopSink struct{}
+
+func NewNoopSink() *NoopSink {
+	return &NoopSink{}
+}
+
+func (s *NoopSink) Write(metrics []MetricData) error {
+	return nil
+}
@@ -20,8 +20,11 @@ type GlyphGenerateResponse struct {
 }
 
 const (
-	MetricTypeCPU     = "cpu_metric"
-	MetricTypeMemory  = "memory_metric"
-	MetricTypeNetwork = "network_metric"
-	MetricTypeDisk    = "disk_metric"
+	MetricTypeCPU        = "cpu_metric"
+	MetricTypeMemory     = "memory_metric"
+	MetricTypeNetwork    = "network_metric"
+	MetricTypeDisk       = "disk_metric"
+	MetricTypeInfiniband = "infiniband_metric"
+	MetricTypeGPU        = "gpu_metric"
+	MetricTypeProcess    = "process_metric"
 )
@@ -0,0 +1,311 @@
+// Package spool implements a WAL-style on-disk queue for metric batches
+// that couldn't be delivered to the sink. Batches are appended as
+// length-prefixed, gzip'd records to a rotating set of files under a
+// directory, and replayed back in write order once the sink recovers.
+package spool
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// maxFileBytes is the size at which the active spool file rotates to a new
+// one. SPOOL_MAX_BYTES then caps the total size of the directory across all
+// rotated files.
+const maxFileBytes = 8 * 1024 * 1024
+
+type Spool struct {
+	dir      string
+	maxBytes int64
+
+	mu       sync.Mutex
+	file     *os.File
+	fileSize int64
+	seq      int
+}
+
+// Open creates dir if needed and opens (or resumes) the newest spool file
+// in it for appending.
+func Open(dir string, maxBytes int64) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create spool dir: %w", err)
+	}
+
+	s := &Spool{dir: dir, maxBytes: maxBytes}
+
+	seq, err := latestSeq(dir)
+	if err != nil {
+		return nil, err
+	}
+	s.seq = seq
+	if s.seq == 0 {
+		s.seq = 1
+	}
+
+	if err := s.openFile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func latestSeq(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("list spool dir: %w", err)
+	}
+
+	max := 0
+	for _, e := range entries {
+		var n int
+		if _, err := fmt.Sscanf(e.Name(), "%06d.spool", &n); err == nil && n > max {
+			max = n
+		}
+	}
+	return max, nil
+}
+
+func (s *Spool) openFile() error {
+	path := filepath.Join(s.dir, fmt.Sprintf("%06d.spool", s.seq))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open spool file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("stat spool file: %w", err)
+	}
+
+	s.file = f
+	s.fileSize = info.Size()
+	return nil
+}
+
+// Append gzips data and appends it as a length-prefixed record to the
+// active spool file, rotating and pruning old files as needed.
+func (s *Spool) Append(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		gz.Close()
+		return fmt.Errorf("gzip spool record: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("gzip spool record: %w", err)
+	}
+	record := buf.Bytes()
+
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(record)))
+
+	if _, err := s.file.Write(header[:]); err != nil {
+		return fmt.Errorf("write spool record header: %w", err)
+	}
+	if _, err := s.file.Write(record); err != nil {
+		return fmt.Errorf("write spool record: %w", err)
+	}
+	s.fileSize += int64(len(header)) + int64(len(record))
+
+	if s.fileSize >= maxFileBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	return s.enforceCap()
+}
+
+func (s *Spool) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("close spool file: %w", err)
+	}
+	s.seq++
+	return s.openFile()
+}
+
+// enforceCap deletes the oldest completed spool files (never the file
+// currently being written) until the directory is back under maxBytes.
+func (s *Spool) enforceCap() error {
+	if s.maxBytes <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var total int64
+	sizes := make([]int64, len(entries))
+	for i, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		sizes[i] = info.Size()
+		total += info.Size()
+	}
+
+	activeName := filepath.Base(s.file.Name())
+	for i := 0; i < len(entries) && total > s.maxBytes; i++ {
+		if entries[i].Name() == activeName {
+			break
+		}
+		if err := os.Remove(filepath.Join(s.dir, entries[i].Name())); err == nil {
+			total -= sizes[i]
+			log.Printf("Spool directory over SPOOL_MAX_BYTES (%d), dropped %s (%d bytes) undelivered", s.maxBytes, entries[i].Name(), sizes[i])
+		}
+	}
+
+	return nil
+}
+
+// Replay first rotates the active file if it holds any records, then calls
+// fn for every spooled record across all completed files, in write order,
+// removing each file once every record in it has been handed
+// to fn successfully. It stops at the first error fn returns, after
+// dropping the records from that file that were already handed to fn
+// successfully, so a still-unreachable sink loses nothing and a later
+// Replay doesn't redeliver those as duplicates.
+func (s *Spool) Replay(ctx context.Context, fn func([]byte) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fileSize > 0 {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("list spool dir: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	activeName := filepath.Base(s.file.Name())
+	for _, e := range entries {
+		if e.Name() == activeName {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		path := filepath.Join(s.dir, e.Name())
+		if err := replayFile(path, fn); err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("remove replayed spool file %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// replayFile hands every record in path to fn in order. If fn fails partway
+// through, the records already handed to fn successfully are dropped from
+// the file before returning, so the next Replay pass resumes at the failed
+// record instead of redelivering everything before it as duplicates.
+func replayFile(path string, fn func([]byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open spool file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	for {
+		offset, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return fmt.Errorf("seek spool file %s: %w", path, err)
+		}
+
+		var header [4]byte
+		if _, err := io.ReadFull(f, header[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("read spool record header: %w", err)
+		}
+
+		length := binary.BigEndian.Uint32(header[:])
+		record := make([]byte, length)
+		if _, err := io.ReadFull(f, record); err != nil {
+			return fmt.Errorf("read spool record: %w", err)
+		}
+
+		gz, err := gzip.NewReader(bytes.NewReader(record))
+		if err != nil {
+			return fmt.Errorf("gunzip spool record: %w", err)
+		}
+		data, err := io.ReadAll(gz)
+		gz.Close()
+		if err != nil {
+			return fmt.Errorf("read spool record: %w", err)
+		}
+
+		if err := fn(data); err != nil {
+			if dropErr := dropConsumedRecords(path, f, offset); dropErr != nil {
+				log.Printf("Failed to drop already-replayed records from %s: %v", path, dropErr)
+			}
+			return err
+		}
+	}
+}
+
+// dropConsumedRecords rewrites path to contain only the bytes from offset
+// onward, i.e. the record fn just failed on plus everything after it. It's
+// a no-op when offset is 0, since nothing has been consumed yet.
+func dropConsumedRecords(path string, f *os.File, offset int64) error {
+	if offset == 0 {
+		return nil
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("seek to unconsumed records: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("create replacement spool file: %w", err)
+	}
+
+	if _, err := io.Copy(tmp, f); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("copy unconsumed records: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("close replacement spool file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replace spool file with unconsumed records: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the active spool file.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}